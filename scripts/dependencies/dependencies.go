@@ -1,16 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/release-sdk/git"
-	"sigs.k8s.io/release-utils/command"
+
+	"github.com/cri-o/cri-o/scripts/dependencies/internal/gocommand"
+)
+
+// Sentinel errors identifying specific failure modes, so callers (CI
+// wrappers, other tooling) can branch on the cause with errors.Is instead of
+// pattern-matching error strings.
+var (
+	// ErrGoList is returned when `go list` fails to enumerate modules.
+	ErrGoList = errors.New("go list failed")
+	// ErrGitPush is returned when pushing the report to the gh-pages branch fails.
+	ErrGitPush = errors.New("git push failed")
+	// ErrNoToken is returned when the GITHUB_TOKEN environment variable is
+	// unset, so the report was generated but not published. When a reachable
+	// vulnerability was also found, ErrReachableVulnerability is returned
+	// instead.
+	ErrNoToken = errors.New("no github token set")
+	// ErrReachableVulnerability is returned when the vulnerability scan
+	// found an advisory that is actually reachable from cri-o's code, so
+	// CI can fail the build via errors.Is instead of grepping the report.
+	ErrReachableVulnerability = errors.New("reachable vulnerabilities found")
 )
 
 const (
@@ -19,135 +41,177 @@ const (
 	tokenKey = "GITHUB_TOKEN"
 )
 
-var outputPath string
+var (
+	outputPath string
+	vuln       bool
+	format     string
+	timeout    time.Duration
+)
 
 func main() {
 	// Parse CLI flags
 	flag.StringVar(&outputPath,
 		"output-path", "", "the output path for the release notes",
 	)
+	flag.BoolVar(&vuln,
+		"vuln", false, "scan dependencies for known vulnerabilities with govulncheck",
+	)
+	flag.StringVar(&format,
+		"format", string(formatMarkdown), "the report format, one of: markdown, json, cyclonedx",
+	)
+	flag.DurationVar(&timeout,
+		"timeout", 5*time.Minute, "overall timeout for the go, govulncheck and git subprocess calls of a run",
+	)
 	flag.Parse()
 
 	logrus.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
 	if err := run(); err != nil {
+		if errors.Is(err, ErrNoToken) {
+			logrus.Info(err)
+			return
+		}
+		if errors.Is(err, ErrReachableVulnerability) {
+			logrus.Fatalf("Reachable vulnerabilities found, see %s", reportFileName(outputFormat(format)))
+		}
 		logrus.Fatalf("Unable to %v", err)
 	}
 }
 
 func run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
 	// Ensure output path
 	logrus.Infof("Ensuring output path %s", outputPath)
 	if err := os.MkdirAll(outputPath, 0o755); err != nil {
-		return errors.Wrap(err, "create output path")
+		return fmt.Errorf("create output path: %w", err)
 	}
 
 	// Generate the report
 	logrus.Infof("Getting go modules")
 	if err := os.Setenv("GOSUMDB", "off"); err != nil {
-		return errors.Wrap(err, "disabling GOSUMDB")
-	}
-	modules, err := command.New(
-		"go", "list", "--mod=mod", "-u", "-m", "--json", "all",
-	).RunSilentSuccessOutput()
-	if err != nil {
-		return errors.Wrap(err, "listing go modules")
+		return fmt.Errorf("disabling GOSUMDB: %w", err)
 	}
-	tmpFile, err := os.CreateTemp("", "modules-")
+	modulesOutput, err := (&gocommand.Invocation{
+		Verb: "go",
+		Args: []string{"list", "--mod=mod", "-u", "-m", "--json", "all"},
+	}).Run(ctx)
 	if err != nil {
-		return errors.Wrap(err, "creating temp file")
-	}
-	if _, err := tmpFile.WriteString(modules.OutputTrimNL()); err != nil {
-		return errors.Wrap(err, "writing to temp file")
+		return fmt.Errorf("listing go modules: %w: %w", ErrGoList, err)
 	}
 
-	logrus.Infof("Retrieving outdated dependencies")
-	outdated, err := command.New("cat", tmpFile.Name()).
-		Pipe("./build/bin/go-mod-outdated", "--direct", "--update", "--style=markdown").
-		RunSuccessOutput()
+	logrus.Infof("Analyzing modules")
+	modules, err := parseModules(strings.NewReader(modulesOutput))
 	if err != nil {
-		return errors.Wrap(err, "retrieving outdated dependencies")
+		return fmt.Errorf("parsing modules: %w", err)
 	}
 
-	logrus.Infof("Retrieving all dependencies")
-	all, err := command.New("cat", tmpFile.Name()).
-		Pipe("./build/bin/go-mod-outdated", "--style=markdown").
-		RunSuccessOutput()
-	if err != nil {
-		return errors.Wrap(err, "retrieving all dependencies")
+	var vulnerabilities []vulnFinding
+	reachableVuln := false
+	if vuln {
+		var err error
+		vulnerabilities, err = getVulnerabilities(ctx)
+		if err != nil {
+			return fmt.Errorf("scanning for vulnerabilities: %w", err)
+		}
+		reachableVuln = hasReachableVulnerability(vulnerabilities)
 	}
 
-	// Write the output
-	outputFile := filepath.Join(outputPath, file)
-	os.RemoveAll(outputFile)
-
 	repo, err := git.OpenRepo(".")
 	if err != nil {
-		return errors.Wrap(err, "open local repo")
+		return fmt.Errorf("open local repo: %w", err)
 	}
 
 	head, err := repo.Head()
 	if err != nil {
-		return errors.Wrap(err, "get repository HEAD")
+		return fmt.Errorf("get repository HEAD: %w", err)
 	}
 
-	content := fmt.Sprintf(`# CRI-O Dependency Report
-
-_Generated on %s for commit [%s][0]._
-
-[0]: https://github.com/cri-o/cri-o/commit/%s
-
-## Outdated Dependencies
+	report := Report{
+		GeneratedAt:     time.Now(),
+		Commit:          head,
+		Modules:         modules,
+		Outdated:        renderOutdatedTable(modules),
+		All:             renderAllTable(modules),
+		Vulnerabilities: vulnerabilities,
+	}
 
-%s
+	reportFormat := outputFormat(format)
+	content, err := renderReport(ctx, reportFormat, report)
+	if err != nil {
+		return fmt.Errorf("rendering report: %w", err)
+	}
 
-## All Dependencies
+	reportFile := reportFileName(reportFormat)
 
-%s
-`,
-		time.Now().Format(time.RFC1123),
-		head[:7], head,
-		outdated.OutputTrimNL(),
-		all.OutputTrimNL(),
-	)
+	// Write the output
+	outputFile := filepath.Join(outputPath, reportFile)
+	os.RemoveAll(outputFile)
 
-	if err := os.WriteFile(outputFile, []byte(content), 0o644); err != nil {
-		return errors.Wrap(err, "writing report")
+	if err := os.WriteFile(outputFile, content, 0o644); err != nil {
+		return fmt.Errorf("writing report: %w", err)
 	}
 
 	token, tokenSet := os.LookupEnv(tokenKey)
 	if !tokenSet || token == "" {
-		logrus.Infof("%s environment variable is not set", tokenKey)
-		os.Exit(0)
+		if reachableVuln {
+			return ErrReachableVulnerability
+		}
+		return fmt.Errorf("%s environment variable is not set: %w", tokenKey, ErrNoToken)
 	}
 
 	currentBranch, err := repo.CurrentBranch()
 	if err != nil {
-		return errors.Wrap(err, "get current branch")
+		return fmt.Errorf("get current branch: %w", err)
 	}
 
 	logrus.Infof("Checking out branch %s", branch)
-	if err := repo.Checkout(branch); err != nil {
-		return errors.Wrapf(err, "checkout %s branch", branch)
+	if err := gitInvoke(ctx, "checkout", branch); err != nil {
+		return fmt.Errorf("checkout %s branch: %w", branch, err)
 	}
-	defer func() { err = repo.Checkout(currentBranch) }()
+	defer func() {
+		if err := gitInvoke(ctx, "checkout", currentBranch); err != nil {
+			logrus.Errorf("checkout back to %s branch: %v", currentBranch, err)
+		}
+	}()
 
 	// Write the target file
-	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
-		return errors.Wrap(err, "write content to file")
+	if err := os.WriteFile(reportFile, content, 0o644); err != nil {
+		return fmt.Errorf("write content to file: %w", err)
 	}
 
-	if err := repo.Add(file); err != nil {
-		return errors.Wrap(err, "add file to repo")
+	if err := gitInvoke(ctx, "add", reportFile); err != nil {
+		return fmt.Errorf("add file to repo: %w", err)
 	}
 
 	// Publish the changes
-	if err := repo.Commit("Update dependency report"); err != nil {
-		return errors.Wrap(err, "commit")
+	if err := gitInvoke(ctx,
+		"-c", "user.name="+git.DefaultGitUser,
+		"-c", "user.email="+git.DefaultGitEmail,
+		"commit", "-m", "Update dependency report",
+	); err != nil {
+		return fmt.Errorf("commit: %w", err)
 	}
 
-	if err := repo.Push(branch); err != nil {
-		return errors.Wrap(err, "push changes")
+	if err := gitInvoke(ctx, "push", git.DefaultRemote, branch); err != nil {
+		return fmt.Errorf("push changes: %w: %w", ErrGitPush, err)
+	}
+
+	if reachableVuln {
+		return ErrReachableVulnerability
 	}
 
 	return nil
 }
+
+// gitInvoke runs `git` with the given arguments through gocommand.Invocation
+// instead of the release-sdk git.Repo helpers used for the read-only calls
+// above: Checkout/Add/Commit/Push all shell out in release-sdk too, but
+// without a context, so a hung `git push` behind a broken proxy keeps
+// running even after `-timeout` fires. Routing them through
+// exec.CommandContext means the `-timeout` flag actually kills the
+// subprocess instead of just giving up on waiting for it.
+func gitInvoke(ctx context.Context, args ...string) error {
+	_, err := (&gocommand.Invocation{Verb: "git", Args: args}).Run(ctx)
+	return err
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cri-o/cri-o/scripts/dependencies/internal/gocommand"
+)
+
+// outputFormat identifies one of the supported `-format` values.
+type outputFormat string
+
+const (
+	formatMarkdown  outputFormat = "markdown"
+	formatJSON      outputFormat = "json"
+	formatCycloneDX outputFormat = "cyclonedx"
+)
+
+// Report is the in-memory representation of a single run of the tool. It is
+// built once from the collected module data and then handed to whichever
+// renderer the `-format` flag selected, so every format sees exactly the
+// same inputs.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Commit      string    `json:"commit"`
+	Modules     []Module  `json:"modules"`
+	Outdated    string    `json:"outdatedMarkdown,omitempty"`
+	All         string    `json:"allMarkdown,omitempty"`
+	// No omitempty: a nil slice (scan never ran) and an empty slice (scan
+	// ran, found nothing) must stay distinguishable in the JSON/CycloneDX
+	// output, the same way renderMarkdown's nil check already relies on.
+	Vulnerabilities []vulnFinding `json:"vulnerabilities"`
+}
+
+// reportFileName returns the file name the report should be written to for
+// a given format, preserving the historical "dependencies.md" name for
+// markdown.
+func reportFileName(format outputFormat) string {
+	switch format {
+	case formatJSON:
+		return "dependencies.json"
+	case formatCycloneDX:
+		return "dependencies.cdx.json"
+	default:
+		return file
+	}
+}
+
+// renderReport dispatches a built Report to the renderer selected by format,
+// returning the bytes to write to the output file.
+func renderReport(ctx context.Context, format outputFormat, report Report) ([]byte, error) {
+	switch format {
+	case formatMarkdown:
+		return []byte(renderMarkdown(report)), nil
+	case formatJSON:
+		return renderJSON(report)
+	case formatCycloneDX:
+		return renderCycloneDX(ctx, report)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// renderMarkdown produces the human-readable dependency report, the same
+// shape the tool has always emitted.
+func renderMarkdown(report Report) string {
+	content := fmt.Sprintf(`# CRI-O Dependency Report
+
+_Generated on %s for commit [%s][0]._
+
+[0]: https://github.com/cri-o/cri-o/commit/%s
+
+## Outdated Dependencies
+
+%s
+
+## All Dependencies
+
+%s
+`,
+		report.GeneratedAt.Format(time.RFC1123),
+		report.Commit[:7], report.Commit,
+		report.Outdated,
+		report.All,
+	)
+
+	if report.Vulnerabilities != nil {
+		content += fmt.Sprintf(`
+## Known Vulnerabilities
+
+%s
+`, renderVulnerabilities(report.Vulnerabilities))
+	}
+
+	return content
+}
+
+// renderJSON marshals the Report as-is, giving downstream tooling a
+// machine-readable equivalent of the markdown report.
+func renderJSON(report Report) ([]byte, error) {
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling report: %w", err)
+	}
+	return out, nil
+}
+
+// cyclonedxBOM is a minimal CycloneDX 1.5 SBOM document, covering only the
+// fields the dependency report needs to populate.
+type cyclonedxBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cyclonedxMetadata    `json:"metadata"`
+	Components   []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Component cyclonedxMetaComponent `json:"component"`
+}
+
+type cyclonedxMetaComponent struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cyclonedxComponent struct {
+	Type     string                  `json:"type"`
+	Name     string                  `json:"name"`
+	Version  string                  `json:"version"`
+	PURL     string                  `json:"purl"`
+	Licenses []cyclonedxLicenseEntry `json:"licenses,omitempty"`
+}
+
+type cyclonedxLicenseEntry struct {
+	License cyclonedxLicense `json:"license"`
+}
+
+type cyclonedxLicense struct {
+	ID string `json:"id,omitempty"`
+}
+
+// renderCycloneDX produces a CycloneDX 1.5 SBOM covering every module in the
+// report, keyed to the report's commit as the SBOM serial number so that the
+// document can be tied back to the exact source revision it describes.
+func renderCycloneDX(ctx context.Context, report Report) ([]byte, error) {
+	bom := cyclonedxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: fmt.Sprintf("urn:cdx:cri-o-dependency-report/%s", report.Commit),
+		Version:      1,
+		Metadata: cyclonedxMetadata{
+			Timestamp: report.GeneratedAt,
+			Component: cyclonedxMetaComponent{
+				Type:    "application",
+				Name:    "cri-o",
+				Version: report.Commit,
+			},
+		},
+	}
+
+	// GOMODCACHE is constant for the whole run, so resolve it once rather
+	// than spawning a `go env` subprocess per module below.
+	gomodcache, err := resolveGOMODCACHE(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("resolving GOMODCACHE: %w", err)
+	}
+
+	for _, m := range report.Modules {
+		// A replace directive changes what Go actually compiles and ships,
+		// so the SBOM must describe the replacement, not the pre-replace
+		// module go.mod names — the same resolution renderAllTable's
+		// "Replace" column documents but doesn't need to substitute, since
+		// it shows both.
+		path, version := m.Path, m.Version
+		if m.Replace != nil {
+			path, version = m.Replace.Path, m.Replace.Version
+		}
+
+		component := cyclonedxComponent{
+			Type:    "library",
+			Name:    path,
+			Version: version,
+			PURL:    fmt.Sprintf("pkg:golang/%s@%s", path, version),
+		}
+		if licenseID := moduleLicenseID(gomodcache, path, version); licenseID != "" {
+			component.Licenses = []cyclonedxLicenseEntry{{License: cyclonedxLicense{ID: licenseID}}}
+		}
+		bom.Components = append(bom.Components, component)
+	}
+
+	out, err := json.MarshalIndent(bom, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling SBOM: %w", err)
+	}
+	return out, nil
+}
+
+// licenseFileNames are the conventional names Go modules use for their
+// license file, checked in order.
+var licenseFileNames = []string{"LICENSE", "LICENSE.txt", "LICENSE.md", "COPYING"}
+
+// moduleLicenseID looks up a module's license in the local module cache
+// (as populated by `go mod download`). It deliberately returns "" rather
+// than an error on any miss: licenses are best-effort metadata for the
+// SBOM, not something worth failing the report over.
+func moduleLicenseID(gomodcache, modulePath, version string) string {
+	dir := moduleCacheDir(gomodcache, modulePath, version)
+
+	for _, name := range licenseFileNames {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		if id := detectLicenseID(content); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// resolveGOMODCACHE shells out to `go env GOMODCACHE` once per run; callers
+// resolving per-module cache paths should reuse its result rather than
+// re-invoking it in a loop.
+func resolveGOMODCACHE(ctx context.Context) (string, error) {
+	return (&gocommand.Invocation{
+		Verb: "go",
+		Args: []string{"env", "GOMODCACHE"},
+	}).Run(ctx)
+}
+
+// moduleCacheDir resolves a module's on-disk location under gomodcache,
+// applying Go's module path escaping (uppercase letters become `!`+lower).
+func moduleCacheDir(gomodcache, modulePath, version string) string {
+	escaped := escapeModulePath(modulePath)
+	return filepath.Join(gomodcache, fmt.Sprintf("%s@%s", escaped, version))
+}
+
+// escapeModulePath implements the module cache escaping rules from
+// golang.org/x/mod/module: each uppercase letter is replaced by `!`
+// followed by its lowercase form.
+func escapeModulePath(modulePath string) string {
+	var buf bytes.Buffer
+	for _, r := range modulePath {
+		if r >= 'A' && r <= 'Z' {
+			buf.WriteByte('!')
+			buf.WriteRune(r - 'A' + 'a')
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// detectLicenseID does a best-effort match of common license texts to their
+// SPDX identifier. It is intentionally simple: exhaustive license detection
+// belongs in a dedicated tool such as go-licenses, not hand-rolled here.
+func detectLicenseID(content []byte) string {
+	switch {
+	case bytes.Contains(content, []byte("Apache License")):
+		return "Apache-2.0"
+	case bytes.Contains(content, []byte("MIT License")):
+		return "MIT"
+	case bytes.Contains(content, []byte("BSD 3-Clause")):
+		return "BSD-3-Clause"
+	case bytes.Contains(content, []byte("GNU LESSER GENERAL PUBLIC LICENSE")):
+		return "LGPL-3.0"
+	default:
+		return ""
+	}
+}
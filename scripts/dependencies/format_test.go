@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestEscapeModulePath(t *testing.T) {
+	cases := map[string]string{
+		"github.com/BurntSushi/toml":       "github.com/!burnt!sushi/toml",
+		"golang.org/x/tools":               "golang.org/x/tools",
+		"github.com/Masterminds/semver/v3": "github.com/!masterminds/semver/v3",
+	}
+
+	for in, want := range cases {
+		if got := escapeModulePath(in); got != want {
+			t.Errorf("escapeModulePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderCycloneDXResolvesReplaceDirectives(t *testing.T) {
+	report := Report{
+		Commit: "abc1234",
+		Modules: []Module{
+			{Path: "github.com/original/module", Version: "v1.0.0"},
+			{
+				Path:    "github.com/original/forked",
+				Version: "v1.0.0",
+				Replace: &Module{Path: "github.com/fork/forked", Version: "v1.0.0-fixed"},
+			},
+		},
+	}
+
+	out, err := renderCycloneDX(context.Background(), report)
+	if err != nil {
+		t.Fatalf("renderCycloneDX: %v", err)
+	}
+
+	var bom cyclonedxBOM
+	if err := json.Unmarshal(out, &bom); err != nil {
+		t.Fatalf("unmarshalling SBOM: %v", err)
+	}
+
+	if len(bom.Components) != 2 {
+		t.Fatalf("expected 2 components, got %d", len(bom.Components))
+	}
+
+	replaced := bom.Components[1]
+	if replaced.Name != "github.com/fork/forked" {
+		t.Errorf("component name = %q, want the replace target", replaced.Name)
+	}
+	if replaced.Version != "v1.0.0-fixed" {
+		t.Errorf("component version = %q, want the replace target's version", replaced.Version)
+	}
+	if want := "pkg:golang/github.com/fork/forked@v1.0.0-fixed"; replaced.PURL != want {
+		t.Errorf("PURL = %q, want %q", replaced.PURL, want)
+	}
+}
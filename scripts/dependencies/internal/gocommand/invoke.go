@@ -0,0 +1,63 @@
+// Package gocommand runs go (and other CLI) subprocesses with context
+// propagation, separate stdout/stderr streams, and structured errors. It
+// mirrors the shape of golang.org/x/tools/internal/gocommand, scaled down to
+// what the dependency-report tool needs.
+package gocommand
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Invocation describes a single subprocess call.
+type Invocation struct {
+	// Verb is the executable to run, e.g. "go" or "govulncheck".
+	Verb string
+	// Args are passed to the executable as-is, in order.
+	Args []string
+}
+
+// String renders the invocation the way it would be typed on a command
+// line, for use in error messages and logs.
+func (i *Invocation) String() string {
+	return strings.TrimSpace(i.Verb + " " + strings.Join(i.Args, " "))
+}
+
+// Run executes the invocation, returning its trimmed stdout on success. On
+// failure it returns an *Error wrapping the underlying exec error, with
+// stderr captured for diagnostics.
+func (i *Invocation) Run(ctx context.Context) (stdout string, err error) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, i.Verb, i.Args...)
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+
+	if runErr := cmd.Run(); runErr != nil {
+		return "", &Error{Invocation: i, Stderr: stderrBuf.String(), Err: runErr}
+	}
+
+	return strings.TrimSpace(stdoutBuf.String()), nil
+}
+
+// Error reports a failed invocation, including the command that was run and
+// its captured stderr, so callers get actionable diagnostics instead of a
+// bare exit-status message.
+type Error struct {
+	Invocation *Invocation
+	Stderr     string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Invocation.String(), e.Err)
+	if e.Stderr != "" {
+		msg += "\n" + e.Stderr
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
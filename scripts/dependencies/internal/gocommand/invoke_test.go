@@ -0,0 +1,78 @@
+package gocommand
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvocationRunSuccess(t *testing.T) {
+	inv := &Invocation{Verb: "sh", Args: []string{"-c", "printf '  hello  '"}}
+
+	out, err := inv.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out != "hello" {
+		t.Errorf("out = %q, want %q", out, "hello")
+	}
+}
+
+func TestInvocationRunFailureCapturesStderr(t *testing.T) {
+	inv := &Invocation{Verb: "sh", Args: []string{"-c", "echo boom >&2; exit 1"}}
+
+	_, err := inv.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+
+	var invErr *Error
+	if !errors.As(err, &invErr) {
+		t.Fatalf("err = %T, want *Error", err)
+	}
+	if !strings.Contains(invErr.Stderr, "boom") {
+		t.Errorf("Stderr = %q, want it to contain %q", invErr.Stderr, "boom")
+	}
+	if invErr.Unwrap() == nil {
+		t.Error("Unwrap() = nil, want the underlying exec error")
+	}
+}
+
+func TestInvocationRunTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// A direct "sleep" (not "sh -c sleep 1") so killing the process closes
+	// its stdout/stderr pipes immediately, instead of leaving sleep running
+	// as an orphaned grandchild of an already-killed shell.
+	inv := &Invocation{Verb: "sleep", Args: []string{"1"}}
+
+	start := time.Now()
+	_, err := inv.Run(ctx)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected the timeout to kill the command, got nil error")
+	}
+	if elapsed > 900*time.Millisecond {
+		t.Errorf("Run took %s, expected the context timeout to kill the process long before the sleep finished", elapsed)
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("ctx.Err() = %v, want context.DeadlineExceeded", ctx.Err())
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	inv := &Invocation{Verb: "go", Args: []string{"list", "all"}}
+	err := &Error{Invocation: inv, Stderr: "module not found", Err: errors.New("exit status 1")}
+
+	got := err.Error()
+	if !strings.Contains(got, "go list all") {
+		t.Errorf("Error() = %q, want it to contain the invocation string", got)
+	}
+	if !strings.Contains(got, "module not found") {
+		t.Errorf("Error() = %q, want it to contain the captured stderr", got)
+	}
+}
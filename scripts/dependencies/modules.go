@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Module mirrors the fields of a single entry in the streaming JSON output
+// of `go list -m -u --json all`. Update and Replace recurse into the same
+// shape, matching how `go list` itself nests them.
+type Module struct {
+	Path       string  `json:"Path"`
+	Version    string  `json:"Version"`
+	Indirect   bool    `json:"Indirect,omitempty"`
+	Deprecated string  `json:"Deprecated,omitempty"`
+	Update     *Module `json:"Update,omitempty"`
+	Replace    *Module `json:"Replace,omitempty"`
+	Main       bool    `json:"Main,omitempty"`
+}
+
+// parseModules decodes the streaming `go list -m -u --json all` output into
+// a flat slice, skipping the main module itself. It replaces the former
+// pipeline through the external go-mod-outdated binary: everything the
+// outdated/all tables need is already present in this single JSON stream.
+func parseModules(r io.Reader) ([]Module, error) {
+	var modules []Module
+	dec := json.NewDecoder(r)
+	for {
+		var m Module
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decoding module: %w", err)
+		}
+		if m.Main {
+			continue
+		}
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// renderOutdatedTable renders the direct, outdated modules (those with a
+// pending Update) as a markdown table.
+func renderOutdatedTable(modules []Module) string {
+	var buf strings.Builder
+	buf.WriteString("| Module | Version | New Version | Major Update | Deprecated |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	rows := 0
+	for _, m := range modules {
+		if m.Indirect || m.Update == nil {
+			continue
+		}
+		fmt.Fprintf(&buf, "| %s | %s | %s | %v | %s |\n",
+			m.Path, m.Version, m.Update.Version, isMajorUpdate(m), m.Deprecated)
+		rows++
+	}
+
+	if rows == 0 {
+		return "All direct dependencies are up to date."
+	}
+	return buf.String()
+}
+
+// renderAllTable renders every module in the build list as a markdown
+// table, flagging indirect dependencies, pending updates, replace
+// directives and deprecation notices.
+func renderAllTable(modules []Module) string {
+	var buf strings.Builder
+	buf.WriteString("| Module | Version | New Version | Major Update | Indirect | Replace | Deprecated |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+
+	for _, m := range modules {
+		newVersion := ""
+		if m.Update != nil {
+			newVersion = m.Update.Version
+		}
+		replace := ""
+		if m.Replace != nil {
+			replace = fmt.Sprintf("%s@%s", m.Replace.Path, m.Replace.Version)
+		}
+		fmt.Fprintf(&buf, "| %s | %s | %s | %v | %v | %s | %s |\n",
+			m.Path, m.Version, newVersion, isMajorUpdate(m), m.Indirect, replace, m.Deprecated)
+	}
+
+	return buf.String()
+}
+
+// isMajorUpdate reports whether a module's pending Update crosses a major
+// version boundary (e.g. v1.x.x -> v2.0.0), which for Go modules usually
+// means a different import path and manual migration, unlike a same-major
+// update that's a drop-in replacement.
+func isMajorUpdate(m Module) bool {
+	if m.Update == nil {
+		return false
+	}
+	current, updated := majorVersion(m.Version), majorVersion(m.Update.Version)
+	return current != "" && updated != "" && current != updated
+}
+
+// majorVersion returns the numeric major version component of a Go module
+// version string such as "v1.2.3" (returning "1"), or "" if it doesn't
+// parse as a version.
+func majorVersion(version string) string {
+	rest := strings.TrimPrefix(version, "v")
+	if rest == version {
+		return ""
+	}
+	if i := strings.IndexByte(rest, '.'); i >= 0 {
+		rest = rest[:i]
+	}
+	if rest == "" {
+		return ""
+	}
+	for _, r := range rest {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return rest
+}
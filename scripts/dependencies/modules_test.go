@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadTestModules(t *testing.T) []Module {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/modules.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	modules, err := parseModules(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+	return modules
+}
+
+func TestParseModulesSkipsMain(t *testing.T) {
+	modules := loadTestModules(t)
+
+	for _, m := range modules {
+		if m.Path == "github.com/cri-o/cri-o" {
+			t.Fatalf("expected main module to be skipped, got %+v", m)
+		}
+	}
+
+	if len(modules) != 5 {
+		t.Fatalf("expected 5 modules, got %d", len(modules))
+	}
+}
+
+func TestRenderOutdatedTableOnlyListsDirectUpdates(t *testing.T) {
+	modules := loadTestModules(t)
+
+	table := renderOutdatedTable(modules)
+
+	if strings.Contains(table, "github.com/pkg/errors") {
+		t.Fatalf("expected indirect module to be excluded from outdated table, got:\n%s", table)
+	}
+	if !strings.Contains(table, "github.com/major-bump/module") {
+		t.Fatalf("expected direct module with pending update in outdated table, got:\n%s", table)
+	}
+}
+
+func TestRenderOutdatedTableFlagsMajorUpdates(t *testing.T) {
+	modules := loadTestModules(t)
+
+	table := renderOutdatedTable(modules)
+
+	for _, line := range strings.Split(table, "\n") {
+		if strings.Contains(line, "github.com/major-bump/module") && !strings.Contains(line, "| true |") {
+			t.Errorf("expected major-bump/module to be flagged as a major update, got:\n%s", line)
+		}
+	}
+}
+
+func TestIsMajorUpdate(t *testing.T) {
+	cases := []struct {
+		name string
+		m    Module
+		want bool
+	}{
+		{"no update", Module{Version: "v1.0.0"}, false},
+		{"patch update", Module{Version: "v1.0.0", Update: &Module{Version: "v1.0.1"}}, false},
+		{"major update", Module{Version: "v1.5.0", Update: &Module{Version: "v2.0.0"}}, true},
+	}
+
+	for _, c := range cases {
+		if got := isMajorUpdate(c.m); got != c.want {
+			t.Errorf("%s: isMajorUpdate() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRenderAllTableIncludesEveryModule(t *testing.T) {
+	modules := loadTestModules(t)
+
+	table := renderAllTable(modules)
+
+	for _, path := range []string{
+		"github.com/sirupsen/logrus",
+		"github.com/pkg/errors",
+		"github.com/deprecated/module",
+		"github.com/replaced/module",
+	} {
+		if !strings.Contains(table, path) {
+			t.Errorf("expected all table to contain %s, got:\n%s", path, table)
+		}
+	}
+
+	if !strings.Contains(table, "v1.2.4") {
+		t.Errorf("expected replace version to be rendered, got:\n%s", table)
+	}
+	if !strings.Contains(table, "use github.com/replacement/module instead") {
+		t.Errorf("expected deprecation notice to be rendered, got:\n%s", table)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/cri-o/cri-o/scripts/dependencies/internal/gocommand"
+)
+
+// vulnFinding is a single OSV advisory reported by `govulncheck -json`,
+// flattened into the fields the report renderer needs. An advisory can
+// affect more than one package within the same module, so the affected
+// packages are kept as a slice rather than collapsed into one.
+type vulnFinding struct {
+	ID          string
+	Affected    []affectedPackage
+	CallStacks  []string
+	Unreachable bool
+}
+
+// affectedPackage is one package+fix-version pair from an OSV advisory's
+// "affected" list.
+type affectedPackage struct {
+	Module  string
+	FixedIn string
+}
+
+// govulncheckFinding mirrors the subset of the `govulncheck -json` output
+// stream that we care about. The real format interleaves multiple message
+// types (osv, finding, progress) as individual JSON objects, so we decode
+// into this loosely-typed shape and pick out what we need.
+type govulncheckFinding struct {
+	OSV *struct {
+		ID       string `json:"id"`
+		Affected []struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			Ranges []struct {
+				Events []struct {
+					Fixed string `json:"fixed"`
+				} `json:"events"`
+			} `json:"ranges"`
+		} `json:"affected"`
+	} `json:"osv,omitempty"`
+
+	Finding *struct {
+		OSV          string `json:"osv"`
+		FixedVersion string `json:"fixed_version"`
+		Trace        []struct {
+			Module   string `json:"module"`
+			Version  string `json:"version"`
+			Function string `json:"function"`
+			Receiver string `json:"receiver"`
+			Position *struct {
+				Filename string `json:"filename"`
+				Line     int    `json:"line"`
+			} `json:"position"`
+		} `json:"trace"`
+	} `json:"finding,omitempty"`
+}
+
+// getVulnerabilities shells out to `govulncheck -json ./...` and parses the
+// resulting newline-delimited JSON stream into a flat, de-duplicated list of
+// findings, one per OSV ID. Call stacks are only populated for findings that
+// are actually reachable from the module's code, which lets the report
+// distinguish "imported but unused" from "actively called" vulnerabilities.
+func getVulnerabilities(ctx context.Context) ([]vulnFinding, error) {
+	logrus.Infof("Running govulncheck")
+
+	output, err := (&gocommand.Invocation{
+		Verb: "govulncheck",
+		Args: []string{"-json", "./..."},
+	}).Run(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running govulncheck: %w", err)
+	}
+
+	findings, err := parseGovulncheckOutput(strings.NewReader(output))
+	if err != nil {
+		return nil, fmt.Errorf("parsing govulncheck output: %w", err)
+	}
+
+	return findings, nil
+}
+
+// parseGovulncheckOutput streams decodes the govulncheck JSON output,
+// accumulating OSV metadata and call-stack traces keyed by OSV ID, and
+// returns the merged, sorted result.
+func parseGovulncheckOutput(r io.Reader) ([]vulnFinding, error) {
+	byID := map[string]*vulnFinding{}
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg govulncheckFinding
+		if err := dec.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("decoding govulncheck message: %w", err)
+		}
+
+		switch {
+		case msg.OSV != nil:
+			f := byID[msg.OSV.ID]
+			if f == nil {
+				f = &vulnFinding{ID: msg.OSV.ID, Unreachable: true}
+				byID[msg.OSV.ID] = f
+			}
+			for _, affected := range msg.OSV.Affected {
+				var fixedIn string
+				for _, rng := range affected.Ranges {
+					for _, event := range rng.Events {
+						if event.Fixed != "" {
+							fixedIn = event.Fixed
+						}
+					}
+				}
+				f.Affected = append(f.Affected, affectedPackage{
+					Module:  affected.Package.Name,
+					FixedIn: fixedIn,
+				})
+			}
+
+		case msg.Finding != nil:
+			f := byID[msg.Finding.OSV]
+			if f == nil {
+				f = &vulnFinding{ID: msg.Finding.OSV}
+				byID[msg.Finding.OSV] = f
+			}
+			f.Unreachable = false
+			if stack := formatCallStack(msg.Finding.Trace); stack != "" {
+				f.CallStacks = append(f.CallStacks, stack)
+			}
+		}
+	}
+
+	findings := make([]vulnFinding, 0, len(byID))
+	for _, f := range byID {
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].ID < findings[j].ID })
+
+	return findings, nil
+}
+
+// formatCallStack renders a govulncheck trace as a single "a.B -> c.D -> ..."
+// line, most-recent call first, matching the order govulncheck emits frames.
+func formatCallStack(trace []struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Function string `json:"function"`
+	Receiver string `json:"receiver"`
+	Position *struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+	} `json:"position"`
+}) string {
+	frames := make([]string, 0, len(trace))
+	for _, frame := range trace {
+		if frame.Function == "" {
+			continue
+		}
+		name := frame.Function
+		if frame.Receiver != "" {
+			name = frame.Receiver + "." + frame.Function
+		}
+		frames = append(frames, name)
+	}
+	if len(frames) == 0 {
+		return ""
+	}
+
+	out := frames[0]
+	for _, frame := range frames[1:] {
+		out += " -> " + frame
+	}
+	return out
+}
+
+// renderVulnerabilities builds the "Known Vulnerabilities" markdown section
+// from a set of findings. Findings with no call stack are still listed, but
+// flagged as unreachable so readers can tell an imported-but-unused
+// vulnerability apart from one that is actively called.
+func renderVulnerabilities(findings []vulnFinding) string {
+	if len(findings) == 0 {
+		return "No known vulnerabilities found."
+	}
+
+	var buf bytes.Buffer
+	for _, f := range findings {
+		fmt.Fprintf(&buf, "### %s\n\n", f.ID)
+		for _, affected := range f.Affected {
+			if affected.FixedIn != "" {
+				fmt.Fprintf(&buf, "- Module: `%s` (fixed in `%s`)\n", affected.Module, affected.FixedIn)
+			} else {
+				fmt.Fprintf(&buf, "- Module: `%s`\n", affected.Module)
+			}
+		}
+
+		if f.Unreachable || len(f.CallStacks) == 0 {
+			buf.WriteString("- Status: imported but not reachable from any call path\n\n")
+			continue
+		}
+
+		buf.WriteString("- Status: reachable\n")
+		buf.WriteString("- Call stacks:\n")
+		for _, stack := range f.CallStacks {
+			fmt.Fprintf(&buf, "  - `%s`\n", stack)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+// hasReachableVulnerability reports whether any finding is actually called
+// from the module's code, as opposed to merely being present in the
+// dependency graph.
+func hasReachableVulnerability(findings []vulnFinding) bool {
+	for _, f := range findings {
+		if !f.Unreachable && len(f.CallStacks) > 0 {
+			return true
+		}
+	}
+	return false
+}
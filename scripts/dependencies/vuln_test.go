@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadTestFindings(t *testing.T) []vulnFinding {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/govulncheck.json")
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	findings, err := parseGovulncheckOutput(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+	return findings
+}
+
+func findFinding(t *testing.T, findings []vulnFinding, id string) vulnFinding {
+	t.Helper()
+	for _, f := range findings {
+		if f.ID == id {
+			return f
+		}
+	}
+	t.Fatalf("finding %s not present in %+v", id, findings)
+	return vulnFinding{}
+}
+
+func TestParseGovulncheckOutputKeepsEveryAffectedPackage(t *testing.T) {
+	findings := loadTestFindings(t)
+
+	f := findFinding(t, findings, "GO-2023-0001")
+	if len(f.Affected) != 2 {
+		t.Fatalf("expected 2 affected packages, got %d: %+v", len(f.Affected), f.Affected)
+	}
+
+	byModule := map[string]string{}
+	for _, a := range f.Affected {
+		byModule[a.Module] = a.FixedIn
+	}
+	if got := byModule["golang.org/x/example/pkg/a"]; got != "1.2.3" {
+		t.Errorf("pkg/a fixed version = %q, want 1.2.3", got)
+	}
+	if got := byModule["golang.org/x/example/pkg/b"]; got != "1.2.4" {
+		t.Errorf("pkg/b fixed version = %q, want 1.2.4", got)
+	}
+}
+
+func TestParseGovulncheckOutputTracksReachability(t *testing.T) {
+	findings := loadTestFindings(t)
+
+	reachable := findFinding(t, findings, "GO-2023-0001")
+	if reachable.Unreachable {
+		t.Error("GO-2023-0001 has a finding message with a trace, expected reachable")
+	}
+	if len(reachable.CallStacks) != 1 {
+		t.Fatalf("expected 1 call stack, got %d", len(reachable.CallStacks))
+	}
+
+	unreachable := findFinding(t, findings, "GO-2023-0002")
+	if !unreachable.Unreachable {
+		t.Error("GO-2023-0002 has no finding message, expected unreachable")
+	}
+}
+
+func TestHasReachableVulnerability(t *testing.T) {
+	findings := loadTestFindings(t)
+
+	if !hasReachableVulnerability(findings) {
+		t.Error("expected at least one reachable vulnerability in fixture")
+	}
+}